@@ -0,0 +1,21 @@
+package main
+
+import "errors"
+
+// ErrNotFound is returned by handlers (or anything they call) when the
+// requested resource doesn't exist. handleError maps it to a 404.
+var ErrNotFound = errors.New("not found")
+
+// ClientError represents a client-caused failure that should be reported
+// with a specific HTTP status, for cases that don't fit ErrNotFound.
+// Handlers that need to re-render a form with field-level errors (e.g.
+// snippetCreatePost) do that directly rather than going through this type,
+// since handleError has no page context to re-render with.
+type ClientError struct {
+	Status int
+	Msg    string
+}
+
+func (e *ClientError) Error() string {
+	return e.Msg
+}
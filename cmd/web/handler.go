@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// appHandler is like http.HandlerFunc, but lets a handler return an error
+// instead of writing an error response itself. Pairing it with the
+// handler adapter below means error-handling policy (which status maps to
+// which error) lives in exactly one place - handleError - instead of being
+// repeated in every handler.
+type appHandler func(http.ResponseWriter, *http.Request) error
+
+// handler adapts an appHandler into a standard http.Handler for
+// registration with http.ServeMux.
+func (app *application) handler(h appHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			app.handleError(w, r, err)
+		}
+	})
+}
+
+// handleError is the single place that decides how an error returned from a
+// handler becomes an HTTP response.
+func (app *application) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	var clientErr *ClientError
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		app.clientError(w, http.StatusNotFound)
+	case errors.As(err, &clientErr):
+		http.Error(w, clientErr.Msg, clientErr.Status)
+	default:
+		app.serverError(w, r, err)
+	}
+}
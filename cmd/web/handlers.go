@@ -1,16 +1,33 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
+
+	"github.com/TurboK1D/snippet-box/internal/models"
+	"github.com/TurboK1D/snippet-box/internal/validator"
 )
 
+// snippetCreateForm is decoded from the POST body of /snippet/create by
+// app.formDecoder. Embedding validator.Validator gives it CheckField/Valid
+// for free, and `form:"-"` keeps the decoder from trying to populate it
+// from form data.
+type snippetCreateForm struct {
+	Title               string `form:"title"`
+	Content             string `form:"content"`
+	Expires             int    `form:"expires"`
+	validator.Validator `form:"-"`
+}
+
 // HTTP Handler Pattern in Go:
 //
-// A handler is any function with signature: func(http.ResponseWriter, *http.Request)
-// Alternatively, implement the http.Handler interface: type Handler interface { ServeHTTP(ResponseWriter, *Request) }
+// Handlers here use the appHandler signature - func(http.ResponseWriter,
+// *http.Request) error - instead of the standard http.HandlerFunc. That
+// error return is what lets handleError (see handler.go) own every error
+// response: a handler just returns the error instead of calling
+// app.serverError/app.clientError itself.
 //
 // The http.ResponseWriter:
 //   - Is an interface that collects the HTTP response
@@ -25,69 +42,86 @@ import (
 // home is the handler for the homepage (GET /).
 // Notice the method receiver: (app *application) means this is a method on our
 // application struct, giving it access to app.logger and other dependencies.
-func (app *application) home(w http.ResponseWriter, r *http.Request) {
-	// Headers should be set before writing the response body.
-	// This identifies the server in response headers.
-	w.Header().Add("server", "go")
-
-	// Execute the base template with nil data.
-	// ts is a package-level variable (see templates.go) containing parsed templates.
-	err := ts.ExecuteTemplate(w, "base", nil)
+func (app *application) home(w http.ResponseWriter, r *http.Request) error {
+	// The Server header (and other security headers) is now set once for
+	// every response by the secureHeaders middleware - see middleware.go.
+
+	snippets, err := app.snippets.Latest()
 	if err != nil {
-		// Centralized error handling - see helper.go for details.
-		// We return early to prevent sending a partial/broken response.
-		app.serverError(w, r, err)
-		return
+		return err
 	}
+
+	data := newTemplateData(r)
+	data.Snippets = snippets
+
+	// render looks up "home.html" in app.templateCache, executes it into a
+	// buffer, and only then writes it to w - see templates.go.
+	return app.render(w, r, http.StatusOK, "home.html", data)
 }
 
 // snippetView displays a single snippet by ID.
 // r.PathValue("id") extracts the {id} from the route pattern /snippet/view/{id}
 // The return type is string, so we must convert with strconv.Atoi().
-func (app *application) snippetView(w http.ResponseWriter, r *http.Request) {
+func (app *application) snippetView(w http.ResponseWriter, r *http.Request) error {
 	app.logger.Info("snippet-view accessed")
 
 	// Extract and validate the ID from the URL path.
 	i, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil || i < 1 {
-		// http.NotFound is a convenient shortcut for 404 responses.
-		// It writes the status and a simple body automatically.
-		http.NotFound(w, r)
-		return
+		return ErrNotFound
 	}
 
-	// fmt.Fprintf formats and writes directly to the ResponseWriter.
-	// This is simpler than fmt.Fprint + w.Write() but less flexible.
-	if _, err := fmt.Fprintf(w, "Display specific snippet with ID: %d", i); err != nil {
-		// We log but don't return a 500 - partial writes are acceptable here.
-		log.Printf("write error: %v", err)
+	snippet, err := app.snippets.Get(i)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return ErrNotFound
+		}
+		return err
 	}
+
+	data := newTemplateData(r)
+	data.Snippet = snippet
+
+	return app.render(w, r, http.StatusOK, "view.html", data)
 }
 
-// snippetCreate demonstrates path parameter capture with {path...}.
-// The ellipsis (...) captures multiple path segments as a single value.
-func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) {
+// snippetCreate shows the form for creating a new snippet.
+func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) error {
 	app.logger.Info("snippet-create accessed")
-	w.Header().Set("content-type", "text/plain;charset=utf-8")
 
-	// r.PathValue("path") captures the wildcard path segment(s).
-	path := r.PathValue("path")
-	if _, err := fmt.Fprintf(w, "Captured paths: %s", path); err != nil {
-		log.Printf("write error: %v", err)
-	}
+	data := newTemplateData(r)
+	data.Form = snippetCreateForm{Expires: 365}
+
+	return app.render(w, r, http.StatusOK, "create.html", data)
 }
 
 // snippetCreatePost handles POST requests to create new snippets.
 // The path doesn't have wildcards - it matches exactly /snippet/create.
-func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request) {
+func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request) error {
 	app.logger.Info("snippet-create-post accessed")
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
-	// WriteHeader before Write - headers are sent when Write() is called,
-	// but explicit WriteHeader gives more control over the status code.
-	w.WriteHeader(http.StatusCreated) // 201 Created
+	var form snippetCreateForm
+	if err := app.formDecoder.DecodePostForm(r, &form); err != nil {
+		return &ClientError{Status: http.StatusBadRequest, Msg: "invalid form submission"}
+	}
+
+	form.CheckField(validator.NotBlank(form.Title), "Title", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Title, 100), "Title", "This field cannot be more than 100 characters long")
+	form.CheckField(validator.NotBlank(form.Content), "Content", "This field cannot be blank")
+	form.CheckField(validator.PermittedInt(form.Expires, 1, 7, 365), "Expires", "This field must equal 1, 7 or 365")
+
+	if !form.Valid() {
+		data := newTemplateData(r)
+		data.Form = form
 
-	if _, err := fmt.Fprintf(w, "Snippet created successfully"); err != nil {
-		log.Printf("write error: %v", err)
+		return app.render(w, r, http.StatusUnprocessableEntity, "create.html", data)
 	}
+
+	id, err := app.snippets.Insert(form.Title, form.Content, form.Expires)
+	if err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
+	return nil
 }
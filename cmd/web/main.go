@@ -1,10 +1,21 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
 	"flag"
+	"html/template"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/TurboK1D/snippet-box/internal/forms"
+	"github.com/TurboK1D/snippet-box/internal/models"
 )
 
 // application struct groups all dependencies shared across HTTP handlers.
@@ -15,6 +26,42 @@ import (
 // of shared state if needed, and avoids copying the struct on every call.
 type application struct {
 	logger *slog.Logger
+
+	// cspPolicy is the Content-Security-Policy header value applied by
+	// secureHeaders. tlsEnabled tells secureHeaders whether it's safe to
+	// send Strict-Transport-Security (doing so over plain HTTP would be
+	// misleading, since the browser would still have reached us unencrypted).
+	cspPolicy  string
+	tlsEnabled bool
+
+	// templateCache holds one parsed *template.Template per page, built
+	// once at startup by routes() via newTemplateCache.
+	templateCache map[string]*template.Template
+
+	// snippets is the persistence layer the snippet handlers use. Handlers
+	// depend on the models.SnippetModel interface, not *sql.DB directly.
+	snippets models.SnippetModel
+
+	// formDecoder decodes POST bodies into form structs - see
+	// snippetCreatePost.
+	formDecoder *forms.Decoder
+}
+
+// openDB opens a sqlite connection pool for dsn and verifies it's reachable
+// before returning. sql.Open doesn't actually connect, so without the Ping
+// a bad DSN would only surface on the first request instead of at startup.
+func openDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
 }
 
 func main() {
@@ -22,6 +69,29 @@ func main() {
 	// This is idiomatic Go: use pointers for optional/parsed values.
 	addr := flag.String("addr", ":4000", "HTTP network address")
 
+	// Server timeouts. These protect us from slow or malicious clients
+	// (slowloris-style attacks) and from connections that never close.
+	// Without them, http.Server uses zero values, which mean "no timeout".
+	readTimeout := flag.Duration("read-timeout", 5*time.Second, "Maximum duration for reading the entire request")
+	readHeaderTimeout := flag.Duration("read-header-timeout", 5*time.Second, "Maximum duration for reading request headers")
+	writeTimeout := flag.Duration("write-timeout", 10*time.Second, "Maximum duration before timing out writes of the response")
+	idleTimeout := flag.Duration("idle-timeout", 2*time.Minute, "Maximum duration to wait for the next request on a keep-alive connection")
+	maxHeaderBytes := flag.Int("max-header-bytes", 1<<20, "Maximum size in bytes of request headers")
+
+	// How long to let in-flight requests finish before the process exits
+	// on SIGINT/SIGTERM.
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "Time to wait for in-flight requests to finish during a graceful shutdown")
+
+	// If both of these are set, the server switches to HTTPS via
+	// ListenAndServeTLS instead of plain ListenAndServe.
+	tlsCert := flag.String("tls-cert", "", "Path to TLS certificate file (enables HTTPS)")
+	tlsKey := flag.String("tls-key", "", "Path to TLS private key file (enables HTTPS)")
+
+	csp := flag.String("csp", "default-src 'self'; style-src 'self'; script-src 'self'; img-src 'self' data:",
+		"Content-Security-Policy header value")
+
+	dsn := flag.String("dsn", "snippetbox.db", "SQLite data source name")
+
 	// Parse flags (must call before using *addr)
 	flag.Parse()
 
@@ -29,30 +99,114 @@ func main() {
 	// slog is Go 1.21+'s built-in structured logging (replaces log.Printf).
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+	useTLS := *tlsCert != "" && *tlsKey != ""
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		logger.Error("failed to open database", slog.Any("err", err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	snippets, err := models.NewSnippetModel(db)
+	if err != nil {
+		logger.Error("failed to initialize snippet model", slog.Any("err", err))
+		os.Exit(1)
+	}
+
 	// Initialize application with its dependencies.
 	// Note: we're passing a pointer to application, not a copy.
 	app := &application{
-		logger: logger,
+		logger:      logger,
+		cspPolicy:   *csp,
+		tlsEnabled:  useTLS,
+		snippets:    snippets,
+		formDecoder: forms.NewDecoder(),
 	}
 
 	// http.Server is Go's built-in HTTP server.
-	// It handles graceful shutdown, timeouts, and concurrency automatically.
 	// Setting Handler to app.routes() (which returns *http.ServeMux)
 	// means all routing is delegated to our routes() method.
 	server := &http.Server{
-		Addr:    *addr,              // Network address to listen on
-		Handler: app.routes(),       // Request multiplexer (router)
+		Addr: *addr, // Network address to listen on
+
+		// recoverPanic and logRequest wrap every request: panics become a
+		// logged 500 instead of crashing the process, and every request
+		// gets one structured access log line. logRequest must be outermost:
+		// it logs after next.ServeHTTP returns (not via defer), so a panic
+		// has to be recovered by recoverPanic before it reaches logRequest,
+		// or the access log line would never be reached.
+		Handler: app.logRequest(app.recoverPanic(app.routes())),
+
+		// ErrorLog receives messages the net/http package itself logs
+		// (e.g. panics recovered per-connection, malformed requests).
+		// Routing it through slog keeps everything in one structured stream.
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
+
+		ReadTimeout:       *readTimeout,
+		ReadHeaderTimeout: *readHeaderTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+		MaxHeaderBytes:    *maxHeaderBytes,
 	}
 
-	// Log before starting - this won't block.
-	app.logger.Info("server started", slog.String("addr", *addr))
+	if useTLS {
+		// A hardened tls.Config: require TLS 1.2+, and restrict cipher
+		// suites to ones that support forward secrecy. NextProtos enables
+		// HTTP/2 (h2) over TLS, falling back to HTTP/1.1.
+		server.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			CipherSuites: []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+				tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+			},
+			NextProtos: []string{"h2", "http/1.1"},
+		}
+	}
 
-	// ListenAndServe blocks until the server returns an error.
-	// Common errors: address in use, permission denied.
-	if err := server.ListenAndServe(); err != nil {
-		// Log and exit - Go programs typically use os.Exit(1) for fatal errors.
-		// Note: http.ErrServerClosed is expected during graceful shutdown.
-		app.logger.Error("server error", slog.Any("err", err))
-		os.Exit(1)
+	// signal.NotifyContext gives us a context that's cancelled the moment
+	// the process receives SIGINT or SIGTERM, so we can start draining
+	// in-flight requests instead of dying mid-request.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		app.logger.Info("server started", slog.String("addr", *addr), slog.Bool("tls", useTLS))
+
+		if useTLS {
+			serverErrors <- server.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			serverErrors <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serverErrors:
+		// http.ErrServerClosed is returned by Shutdown/Close, not a real
+		// failure - only treat anything else as fatal.
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.logger.Error("server error", slog.Any("err", err))
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		stop()
+		app.logger.Info("shutting down server", slog.Duration("timeout", *shutdownTimeout))
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+
+		// Shutdown stops accepting new connections and waits for active
+		// requests to finish, up to shutdownCtx's deadline.
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			app.logger.Error("graceful shutdown failed", slog.Any("err", err))
+			os.Exit(1)
+		}
 	}
+
+	app.logger.Info("server stopped")
 }
@@ -0,0 +1,38 @@
+package main
+
+import "net/http"
+
+// wrappedResponseWriter decorates an http.ResponseWriter to capture the
+// status code and number of bytes written, so that middleware (logRequest)
+// can report them after the handler has run without changing the handler's
+// own use of w.
+type wrappedResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	bytes       int
+	wroteHeader bool
+}
+
+// newWrappedResponseWriter returns a wrappedResponseWriter ready for use.
+// The status defaults to 200, matching the net/http behaviour when a
+// handler writes a body without calling WriteHeader first.
+func newWrappedResponseWriter(w http.ResponseWriter) *wrappedResponseWriter {
+	return &wrappedResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+}
+
+func (wr *wrappedResponseWriter) WriteHeader(statusCode int) {
+	wr.ResponseWriter.WriteHeader(statusCode)
+
+	if !wr.wroteHeader {
+		wr.statusCode = statusCode
+		wr.wroteHeader = true
+	}
+}
+
+func (wr *wrappedResponseWriter) Write(b []byte) (int, error) {
+	wr.wroteHeader = true
+
+	n, err := wr.ResponseWriter.Write(b)
+	wr.bytes += n
+	return n, err
+}
@@ -21,10 +21,10 @@ import (
 //   "/{$}"           - Exact match with optional trailing slash handling
 //   "GET /path"      - Method-specific route (Go 1.22+)
 
-// routes returns an http.ServeMux with all application routes registered.
-// The ServeMux acts as both a router and a handler - it receives all requests
-// and dispatches them to the appropriate handler based on the URL pattern.
-func (app *application) routes() *http.ServeMux {
+// routes builds the application's ServeMux and wraps it with secureHeaders,
+// returning the result as a plain http.Handler (main wraps it further with
+// recoverPanic/logRequest).
+func (app *application) routes() http.Handler {
 	// Create a new ServeMux (request multiplexer).
 	// ServeMux matches URL patterns against registered handlers.
 	mux := http.NewServeMux()
@@ -35,10 +35,12 @@ func (app *application) routes() *http.ServeMux {
 	// The "GET /{$}" pattern:
 	//   - GET: only matches GET requests
 	//   - /{$}: root path, {$} handles optional trailing slash
-	mux.HandleFunc("GET /{$}", app.home)
-	mux.HandleFunc("GET /snippet/view/{id}", app.snippetView)
-	mux.HandleFunc("GET /snippet/create/{path...}", app.snippetCreate)
-	mux.HandleFunc("POST /snippet/create", app.snippetCreatePost)
+	// app.handler adapts each appHandler so its returned error is routed
+	// through app.handleError - see handler.go.
+	mux.Handle("GET /{$}", app.handler(app.home))
+	mux.Handle("GET /snippet/view/{id}", app.handler(app.snippetView))
+	mux.Handle("GET /snippet/create", app.handler(app.snippetCreate))
+	mux.Handle("POST /snippet/create", app.handler(app.snippetCreatePost))
 
 	// Serve static files from the embedded filesystem.
 	// Middleware wraps the FileServer to add cache headers.
@@ -48,13 +50,17 @@ func (app *application) routes() *http.ServeMux {
 	// The "/static/" prefix is stripped before looking up the file.
 	mux.Handle("GET /static/", cacheControl(http.FileServer(http.FS(staticFiles))))
 
-	// Load templates on startup.
-	// If templates fail to load, we can't serve HTML - exit with an error.
+	// Build the template cache on startup.
+	// If templates fail to parse, we can't serve HTML - exit with an error.
 	// This is "fail fast" behavior: better to crash at startup than serve errors.
-	if err := loadTemplates(); err != nil {
-		app.logger.Error("failed to load templates", slog.Any("err", err))
+	templateCache, err := newTemplateCache()
+	if err != nil {
+		app.logger.Error("failed to build template cache", slog.Any("err", err))
 		os.Exit(1)
 	}
+	app.templateCache = templateCache
 
-	return mux
+	// noSurf (CSRF protection) sits inside secureHeaders so its rejection
+	// responses still carry the baseline security headers.
+	return app.secureHeaders(app.noSurf(mux))
 }
@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"embed"
+	"fmt"
 	"html/template"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/justinas/nosurf"
+
+	"github.com/TurboK1D/snippet-box/internal/models"
 )
 
 // Go Embed Pattern (Go 1.16+):
@@ -31,20 +41,88 @@ var htmlFiles embed.FS
 // http.FileServer(http.FS(staticFiles)) serves these files.
 var staticFiles embed.FS
 
-// ts (template set) is a parsed collection of templates.
-// Using a package-level variable avoids re-parsing on every request.
-// In production, you might want to re-parse on startup and cache.
-var ts *template.Template
-
-// loadTemplates reads all HTML files from the embedded file system.
-// template.ParseFS walks the directory tree, parsing any .html files.
-// The second argument is a glob pattern: "ui/html/**/*.html" means
-// all .html files in ui/html and its subdirectories.
-func loadTemplates() error {
-	var err error
-	ts, err = template.ParseFS(htmlFiles, "ui/html/**/*.html")
+// templateData holds everything a page template might need to render. Every
+// handler builds one of these (via newTemplateData) rather than passing raw
+// values, so adding a new piece of shared data (e.g. CSRFToken) doesn't
+// require changing every handler's signature.
+type templateData struct {
+	CurrentYear int
+	Flash       string
+	CSRFToken   string
+	Snippet     models.Snippet
+	Snippets    []models.Snippet
+	Form        any
+}
+
+// newTemplateData returns a templateData pre-populated with the values every
+// page needs regardless of what it's displaying.
+func newTemplateData(r *http.Request) templateData {
+	return templateData{
+		CurrentYear: time.Now().Year(),
+		CSRFToken:   nosurf.Token(r),
+	}
+}
+
+// newTemplateCache parses each page in ui/html/pages into its own
+// *template.Template, composed with the shared base layout and any
+// partials. Building the whole cache once at startup means a broken
+// template fails fast at boot instead of on the first request that needs
+// it, and avoids re-parsing the same files on every request.
+func newTemplateCache() (map[string]*template.Template, error) {
+	cache := map[string]*template.Template{}
+
+	pages, err := fs.Glob(htmlFiles, "ui/html/pages/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	// Partials are optional: template.ParseFS errors if a pattern matches
+	// no files, so only include the glob when ui/html/partials actually
+	// has something in it.
+	partials, err := fs.Glob(htmlFiles, "ui/html/partials/*.html")
 	if err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		name := filepath.Base(page)
+
+		patterns := []string{"ui/html/base.html"}
+		if len(partials) > 0 {
+			patterns = append(patterns, "ui/html/partials/*.html")
+		}
+		patterns = append(patterns, page)
+
+		ts, err := template.ParseFS(htmlFiles, patterns...)
+		if err != nil {
+			return nil, err
+		}
+
+		cache[name] = ts
+	}
+
+	return cache, nil
+}
+
+// render executes the named page template into an in-memory buffer first,
+// and only writes the status code and body once that succeeds. Writing
+// straight to w would risk sending a half-formed response (plus a spurious
+// 200) if execution fails partway through. Any failure is returned rather
+// than handled here, so it flows through the same handleError path as every
+// other handler error.
+func (app *application) render(w http.ResponseWriter, r *http.Request, status int, page string, data templateData) error {
+	ts, ok := app.templateCache[page]
+	if !ok {
+		return fmt.Errorf("the template %q does not exist", page)
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := ts.ExecuteTemplate(buf, "base", data); err != nil {
 		return err
 	}
-	return nil
+
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
 }
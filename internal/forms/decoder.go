@@ -0,0 +1,42 @@
+// Package forms decodes HTML form submissions into Go structs, tagged with
+// `form:"..."`, via github.com/go-playground/form.
+package forms
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/form/v4"
+)
+
+// Decoder decodes url.Values (as produced by r.ParseForm) into a struct.
+type Decoder struct {
+	decoder *form.Decoder
+}
+
+// NewDecoder returns a Decoder ready for use.
+func NewDecoder() *Decoder {
+	return &Decoder{decoder: form.NewDecoder()}
+}
+
+// DecodePostForm parses r's POST body and decodes it into dst, which must
+// be a non-nil pointer to a struct.
+func (d *Decoder) DecodePostForm(r *http.Request, dst any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	if err := d.decoder.Decode(dst, r.PostForm); err != nil {
+		// InvalidDecoderError means dst isn't a valid target (e.g. not a
+		// pointer to a struct) - that's a programming error, not bad input
+		// from the client, so it should fail loudly rather than be treated
+		// as a 400.
+		var invalidDecoderError *form.InvalidDecoderError
+		if errors.As(err, &invalidDecoderError) {
+			panic(err)
+		}
+		return err
+	}
+
+	return nil
+}
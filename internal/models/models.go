@@ -0,0 +1,41 @@
+// Package models contains the data types and persistence interfaces shared
+// across the application. Handlers depend on the SnippetModel interface
+// defined here, not on a concrete storage engine - see sqlite.go for the
+// one implementation that currently exists.
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoRecord is returned by SnippetModel methods when a query finds no
+// matching row, so callers can distinguish "not found" from a real
+// database error without depending on the underlying driver's error type.
+var ErrNoRecord = errors.New("models: no matching record found")
+
+// Snippet represents a single snippet record.
+type Snippet struct {
+	ID      int
+	Title   string
+	Content string
+	Created time.Time
+	Expires time.Time
+}
+
+// SnippetModel describes the persistence operations the application needs
+// for snippets. Handlers take a SnippetModel rather than a concrete type,
+// so the storage engine can change (or be faked in tests) without the HTTP
+// layer knowing.
+type SnippetModel interface {
+	// Insert creates a new snippet that expires in `expires` days and
+	// returns its ID.
+	Insert(title, content string, expires int) (int, error)
+
+	// Get returns the snippet with the given ID, or ErrNoRecord if it
+	// doesn't exist (or has expired).
+	Get(id int) (Snippet, error)
+
+	// Latest returns the ten most recently created, non-expired snippets.
+	Latest() ([]Snippet, error)
+}
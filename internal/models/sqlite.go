@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver; pure Go, no cgo
+)
+
+//go:embed schema.sql
+var schemaFS embed.FS
+
+// sqliteSnippetModel is a SnippetModel backed by SQLite.
+type sqliteSnippetModel struct {
+	db *sql.DB
+}
+
+// NewSnippetModel runs the embedded schema migration against db and returns
+// a SnippetModel backed by it. db is expected to already be open (and
+// pinged) against a "sqlite" DSN - see cmd/web/main.go.
+func NewSnippetModel(db *sql.DB) (SnippetModel, error) {
+	schema, err := schemaFS.ReadFile("schema.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(string(schema)); err != nil {
+		return nil, fmt.Errorf("running schema migration: %w", err)
+	}
+
+	return &sqliteSnippetModel{db: db}, nil
+}
+
+func (m *sqliteSnippetModel) Insert(title, content string, expires int) (int, error) {
+	stmt := `INSERT INTO snippets (title, content, created, expires)
+	VALUES (?, ?, datetime('now'), datetime('now', ?))`
+
+	result, err := m.db.Exec(stmt, title, content, fmt.Sprintf("+%d days", expires))
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (m *sqliteSnippetModel) Get(id int) (Snippet, error) {
+	stmt := `SELECT id, title, content, created, expires FROM snippets
+	WHERE expires > datetime('now') AND id = ?`
+
+	var s Snippet
+	err := m.db.QueryRow(stmt, id).Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Snippet{}, ErrNoRecord
+		}
+		return Snippet{}, err
+	}
+
+	return s, nil
+}
+
+func (m *sqliteSnippetModel) Latest() ([]Snippet, error) {
+	stmt := `SELECT id, title, content, created, expires FROM snippets
+	WHERE expires > datetime('now') ORDER BY id DESC LIMIT 10`
+
+	rows, err := m.db.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snippets []Snippet
+	for rows.Next() {
+		var s Snippet
+		if err := rows.Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires); err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snippets, nil
+}
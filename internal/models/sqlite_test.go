@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestModel opens a fresh temp-file SQLite database, migrates it, and
+// returns a model backed by it. Using a real file (rather than :memory:)
+// matches how the application actually opens its database in main.go.
+func newTestModel(t *testing.T) *sqliteSnippetModel {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := NewSnippetModel(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m.(*sqliteSnippetModel)
+}
+
+func TestSnippetModelInsertAndGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		content string
+		expires int
+	}{
+		{"short expiry", "Test title", "Test content", 1},
+		{"long expiry", "Another title", "Another content", 365},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+
+			id, err := m.Insert(tt.title, tt.content, tt.expires)
+			if err != nil {
+				t.Fatalf("Insert: %v", err)
+			}
+
+			got, err := m.Get(id)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+
+			if got.ID != id {
+				t.Errorf("got ID %d, want %d", got.ID, id)
+			}
+			if got.Title != tt.title {
+				t.Errorf("got title %q, want %q", got.Title, tt.title)
+			}
+			if got.Content != tt.content {
+				t.Errorf("got content %q, want %q", got.Content, tt.content)
+			}
+		})
+	}
+}
+
+func TestSnippetModelGetNoRecord(t *testing.T) {
+	m := newTestModel(t)
+
+	_, err := m.Get(999)
+	if !errors.Is(err, ErrNoRecord) {
+		t.Errorf("got err %v, want ErrNoRecord", err)
+	}
+}
+
+func TestSnippetModelLatest(t *testing.T) {
+	m := newTestModel(t)
+
+	var lastID int
+	for i := 0; i < 3; i++ {
+		id, err := m.Insert("title", "content", 7)
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		lastID = id
+	}
+
+	snippets, err := m.Latest()
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+
+	if len(snippets) != 3 {
+		t.Fatalf("got %d snippets, want 3", len(snippets))
+	}
+	if snippets[0].ID != lastID {
+		t.Errorf("got first snippet ID %d, want %d (most recent first)", snippets[0].ID, lastID)
+	}
+}
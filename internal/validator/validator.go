@@ -0,0 +1,71 @@
+// Package validator provides small, composable building blocks for
+// validating form input. Handlers embed a Validator in their form structs
+// and call the check functions below via CheckField - see
+// cmd/web/handlers.go.
+package validator
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator accumulates field-level validation failures.
+type Validator struct {
+	FieldErrors map[string]string
+}
+
+// Valid reports whether no validation errors have been recorded.
+func (v *Validator) Valid() bool {
+	return len(v.FieldErrors) == 0
+}
+
+// AddFieldError records message for field, unless one is already recorded
+// for it (so the first failure for a field wins).
+func (v *Validator) AddFieldError(field, message string) {
+	if v.FieldErrors == nil {
+		v.FieldErrors = map[string]string{}
+	}
+
+	if _, exists := v.FieldErrors[field]; !exists {
+		v.FieldErrors[field] = message
+	}
+}
+
+// CheckField adds message for field if ok is false.
+func (v *Validator) CheckField(ok bool, field, message string) {
+	if !ok {
+		v.AddFieldError(field, message)
+	}
+}
+
+// NotBlank reports whether value contains at least one non-whitespace
+// character.
+func NotBlank(value string) bool {
+	return strings.TrimSpace(value) != ""
+}
+
+// MaxChars reports whether value contains no more than n characters.
+func MaxChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) <= n
+}
+
+// MinChars reports whether value contains at least n characters.
+func MinChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) >= n
+}
+
+// PermittedInt reports whether value is one of permitted.
+func PermittedInt(value int, permitted ...int) bool {
+	for _, p := range permitted {
+		if value == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether value matches rx.
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}